@@ -0,0 +1,101 @@
+// Copyright 2021 smzgl@foxmail.com
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// defaultPathExt mirrors cmd.exe's own default when %PATHEXT% isn't set.
+const defaultPathExt = ".COM;.EXE;.BAT;.CMD"
+
+// CandidateBinaryNames is used to get the ordered list of file names to probe for a logical binary name
+func CandidateBinaryNames(name string) []string {
+	exts := pathExtList()
+	candidates := make([]string, 0, len(exts)+1)
+	candidates = append(candidates, name)
+	for _, ext := range exts {
+		if strings.EqualFold(filepath.Ext(name), ext) {
+			continue
+		}
+		candidates = append(candidates, name+ext)
+	}
+	return candidates
+}
+
+// pathExtList parses %PATHEXT% into a slice of dot-prefixed extensions
+func pathExtList() []string {
+	pathext := os.Getenv("PATHEXT")
+	if pathext == "" {
+		pathext = defaultPathExt
+	}
+	parts := strings.Split(pathext, ";")
+	exts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if !strings.HasPrefix(part, ".") {
+			part = "." + part
+		}
+		exts = append(exts, part)
+	}
+	return exts
+}
+
+// LookupBinary is used to find an executable named name, checking extraDirs before PATH, honoring %PATHEXT% on Windows
+func LookupBinary(name string, extraDirs ...string) (string, error) {
+	candidates := CandidateBinaryNames(name)
+
+	for _, dir := range extraDirs {
+		if dir == "" {
+			continue
+		}
+		for _, candidate := range candidates {
+			full := filepath.Join(dir, candidate)
+			if info, err := os.Stat(full); err == nil && !info.IsDir() && isExecutable(info) {
+				return full, nil
+			}
+		}
+	}
+
+	if runtime.GOOS != "windows" {
+		return exec.LookPath(name)
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		for _, candidate := range candidates {
+			full := filepath.Join(dir, candidate)
+			if info, err := os.Stat(full); err == nil && !info.IsDir() {
+				return full, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("util: binary %q not found, tried %s", name, strings.Join(candidates, ", "))
+}
+
+// isExecutable reports whether info looks like an executable file
+func isExecutable(info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode()&0o111 != 0
+}
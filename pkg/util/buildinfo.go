@@ -0,0 +1,104 @@
+// Copyright 2021 smzgl@foxmail.com
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"debug/buildinfo"
+	"errors"
+	"io/fs"
+	"strings"
+)
+
+var (
+	// ErrNotGoBinary is returned by ReadBinaryModuleInfo when path isn't recognized as any executable format at all
+	ErrNotGoBinary = errors.New("util: not a go binary")
+
+	// ErrNoBuildInfo is returned by ReadBinaryModuleInfo when path is a recognized
+	// executable but no Go build info was found in it; debug/buildinfo can't tell
+	// a non-Go-toolchain binary apart from a Go binary with a stripped build info
+	// section, so this error covers both.
+	ErrNoBuildInfo = errors.New("util: go binary has no embedded build info")
+)
+
+// ModuleDep describes one dependency embedded in a Go binary's module info, resolved through any `replace` directive
+type ModuleDep struct {
+	// Path is the effective import path, i.e. the replacement's if the dependency was replaced
+	Path string
+	// Version is the effective version, same replacement rule as Path
+	Version string
+	// IsPseudo reports whether Version is a pseudo-version
+	IsPseudo bool
+	// Replaced reports whether this dependency was satisfied via a `replace` directive
+	Replaced bool
+}
+
+// BinaryModuleInfo is the subset of a Go binary's embedded build info that powerproto cares about
+type BinaryModuleInfo struct {
+	// Path is the main module's path, e.g. "google.golang.org/protobuf"
+	Path string
+	// Version is the main module's version, e.g. "v1.31.0" or a pseudo-version
+	Version string
+	// GoVersion is the Go toolchain version the binary was built with, e.g. "go1.21.0"
+	GoVersion string
+	// Deps is keyed by the effective dependency path (post-replace)
+	Deps map[string]ModuleDep
+}
+
+// ReadBinaryModuleInfo is used to read the module info embedded by the Go toolchain into the binary at path.
+//
+// TODO: not yet wired into an install/verify path (skip-reinstall-if-matching,
+// warn on a "(devel)" main module, diagnostic protobuf/grpc version listing) -
+// this tree has no such path yet.
+func ReadBinaryModuleInfo(path string) (*BinaryModuleInfo, error) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			return nil, err
+		}
+		msg := err.Error()
+		switch {
+		case strings.Contains(msg, "unrecognized file format"):
+			return nil, ErrNotGoBinary
+		case strings.Contains(msg, "not a Go executable"):
+			return nil, ErrNoBuildInfo
+		}
+		return nil, err
+	}
+
+	data := &BinaryModuleInfo{
+		Path:      info.Main.Path,
+		Version:   info.Main.Version,
+		GoVersion: info.GoVersion,
+		Deps:      make(map[string]ModuleDep, len(info.Deps)),
+	}
+
+	for _, dep := range info.Deps {
+		effective := dep
+		replaced := false
+		if dep.Replace != nil {
+			effective = dep.Replace
+			replaced = true
+		}
+		data.Deps[effective.Path] = ModuleDep{
+			Path:     effective.Path,
+			Version:  effective.Version,
+			IsPseudo: IsPseudoVersion(effective.Version),
+			Replaced: replaced,
+		}
+	}
+
+	return data, nil
+}
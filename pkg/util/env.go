@@ -0,0 +1,170 @@
+// Copyright 2021 smzgl@foxmail.com
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RenderWithEnv is used to render string with env, supporting $NAME, ${NAME}, ${NAME:-default} and ${NAME:+alt}
+func RenderWithEnv(s string, ext map[string]string) string {
+	rendered, _ := renderWithEnv(s, ext, nil)
+	return rendered
+}
+
+// RenderWithEnvStrict is like RenderWithEnv but returns an error listing every unresolved variable
+func RenderWithEnvStrict(s string, ext map[string]string) (string, error) {
+	var unresolved []string
+	rendered, _ := renderWithEnv(s, ext, &unresolved)
+	if len(unresolved) > 0 {
+		return "", fmt.Errorf("util: unresolved environment variable(s): %s", strings.Join(unresolved, ", "))
+	}
+	return rendered, nil
+}
+
+// lookupEnv resolves name through ext first, falling back to os.Getenv
+func lookupEnv(name string, ext map[string]string) (string, bool) {
+	val := ext[name]
+	if val == "" {
+		val = os.Getenv(name)
+	}
+	return val, val != ""
+}
+
+func isIdentStart(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func isIdentPart(b byte) bool {
+	return isIdentStart(b) || (b >= '0' && b <= '9')
+}
+
+// renderWithEnv is the shared scanner behind RenderWithEnv and RenderWithEnvStrict
+func renderWithEnv(s string, ext map[string]string, unresolved *[]string) (string, bool) {
+	var buf strings.Builder
+	ok := true
+	n := len(s)
+	for i := 0; i < n; {
+		if s[i] != '$' {
+			buf.WriteByte(s[i])
+			i++
+			continue
+		}
+		if i+1 < n && s[i+1] == '$' {
+			buf.WriteByte('$')
+			i += 2
+			continue
+		}
+		if i+1 < n && s[i+1] == '{' {
+			end := matchBrace(s, i+1)
+			if end == -1 {
+				buf.WriteString(s[i:])
+				return buf.String(), ok
+			}
+			val, resolved := expandBraceExpr(s[i+2:end], ext, unresolved)
+			buf.WriteString(val)
+			ok = ok && resolved
+			i = end + 1
+			continue
+		}
+		j := i + 1
+		if j < n && isIdentStart(s[j]) {
+			j++
+			for j < n && isIdentPart(s[j]) {
+				j++
+			}
+		}
+		if j == i+1 {
+			buf.WriteByte('$')
+			i++
+			continue
+		}
+		name := s[i+1 : j]
+		val, resolved := lookupEnv(name, ext)
+		if resolved {
+			buf.WriteString(val)
+		} else {
+			buf.WriteString(s[i:j])
+			if unresolved != nil {
+				*unresolved = append(*unresolved, name)
+			}
+			ok = false
+		}
+		i = j
+	}
+	return buf.String(), ok
+}
+
+// matchBrace returns the index of the "}" matching the "{" at s[open], or -1, handling nested braces
+func matchBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// expandBraceExpr expands the inside of a "${...}" reference: NAME, NAME:-default, or NAME:+alt
+func expandBraceExpr(expr string, ext map[string]string, unresolved *[]string) (string, bool) {
+	i := 0
+	if i < len(expr) && isIdentStart(expr[i]) {
+		i++
+		for i < len(expr) && isIdentPart(expr[i]) {
+			i++
+		}
+	}
+	name := expr[:i]
+	rest := expr[i:]
+
+	if name == "" {
+		// Not a recognizable reference; leave it exactly as written.
+		return "${" + expr + "}", false
+	}
+
+	val, resolved := lookupEnv(name, ext)
+	switch {
+	case rest == "":
+		if resolved {
+			return val, true
+		}
+		if unresolved != nil {
+			*unresolved = append(*unresolved, name)
+		}
+		return "${" + expr + "}", false
+	case strings.HasPrefix(rest, ":-"):
+		if resolved {
+			return val, true
+		}
+		return renderWithEnv(rest[2:], ext, unresolved)
+	case strings.HasPrefix(rest, ":+"):
+		if !resolved {
+			return "", true
+		}
+		return renderWithEnv(rest[2:], ext, unresolved)
+	default:
+		// Unknown modifier syntax; leave it exactly as written.
+		return "${" + expr + "}", false
+	}
+}
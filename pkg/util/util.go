@@ -15,43 +15,47 @@
 package util
 
 import (
-	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"sort"
 	"strings"
 	"syscall"
+	"time"
 
-	"github.com/coreos/go-semver/semver"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
-// SortSemanticVersion is used to sort semantic version
-func SortSemanticVersion(items []string) ([]string, []string) {
-	versionMap := make(map[*semver.Version]string, len(items))
-	versions := make(semver.Versions, 0, len(items))
-	var malformed []string
-	for _, item := range items {
-		s := item
-		if strings.HasPrefix(s, "v") {
-			s = item[1:]
+// SortSemanticVersion is used to sort semantic version, grouped into regular, pseudo and malformed
+func SortSemanticVersion(items []string) (regular []string, pseudo []string, malformed []string) {
+	canonical := func(item string) string {
+		if !strings.HasPrefix(item, "v") {
+			return "v" + item
 		}
-		version, err := semver.NewVersion(s)
-		if err != nil {
+		return item
+	}
+	for _, item := range items {
+		v := canonical(item)
+		if !semver.IsValid(v) {
 			malformed = append(malformed, item)
 			continue
 		}
-		versionMap[version] = item
-		versions = append(versions, version)
+		if IsPseudoVersion(v) {
+			pseudo = append(pseudo, item)
+			continue
+		}
+		regular = append(regular, item)
 	}
-	sort.Sort(versions)
-	var data []string
-	for _, version := range versions {
-		data = append(data, versionMap[version])
+	byCompare := func(items []string) {
+		sort.Slice(items, func(i, j int) bool {
+			return semver.Compare(canonical(items[i]), canonical(items[j])) < 0
+		})
 	}
+	byCompare(regular)
+	byCompare(pseudo)
 	sort.Strings(malformed)
-	return malformed, data
+	return regular, pseudo, malformed
 }
 
 // DeduplicateSliceStably is used to deduplicate slice items stably
@@ -115,32 +119,38 @@ func GetExitCode(err error) int {
 	return 1
 }
 
-var (
-	regexpEnvironmentVar = regexp.MustCompile(`\$[A-Za-z_]+`)
-	regexpRegularVersion = regexp.MustCompile(`^v[0-9]+\.[0-9]+\.[0-9]+$`)
-)
-
-
-// IsRegularVersion is used to determine whether the version number is a regular version number
-// Regular: va.b.c, and a, b, c are all numbers
+// IsRegularVersion is used to determine whether the version number is a regular, tagged semantic version (not a pseudo-version)
 func IsRegularVersion(s string) bool {
-	return regexpRegularVersion.MatchString(s)
+	v := s
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	return semver.IsValid(v) && !IsPseudoVersion(v)
 }
 
-// RenderWithEnv is used to render string with env
-func RenderWithEnv(s string, ext map[string]string) string {
-	matches := regexpEnvironmentVar.FindAllString(s, -1)
-	for _, match := range matches {
-		key := match[1:]
-		val := ext[key]
-		if val == "" {
-			val = os.Getenv(key)
-		}
-		if val != "" {
-			s = strings.ReplaceAll(s, match, val)
-		}
+// IsPseudoVersion is used to determine whether v is a Go pseudo-version, e.g.
+// vX.0.0-yyyymmddhhmmss-abcdefabcdef or vX.Y.Z-pre.0.yyyymmddhhmmss-abcdefabcdef
+func IsPseudoVersion(v string) bool {
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	return module.IsPseudoVersion(v)
+}
+
+// ExtractPseudoVersionRev is used to pull the embedded commit timestamp and revision hash out of a pseudo-version
+func ExtractPseudoVersionRev(v string) (timestamp time.Time, rev string, ok bool) {
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	t, err := module.PseudoVersionTime(v)
+	if err != nil {
+		return time.Time{}, "", false
 	}
-	return s
+	rev, err = module.PseudoVersionRev(v)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return t.UTC(), rev, true
 }
 
 // RenderPathWithEnv is used to render path with environment
@@ -148,13 +158,17 @@ func RenderPathWithEnv(path string, ext map[string]string) string {
 	return filepath.Clean(RenderWithEnv(path, ext))
 }
 
-// SplitGoPackageVersion is used to split go package version
+// SplitGoPackageVersion is used to split go package version, canonicalizing the version half
 func SplitGoPackageVersion(pkg string) (path string, version string, ok bool) {
 	i := strings.Index(pkg, "@")
 	if i == -1 {
 		return "", "", false
 	}
-	return pkg[:i], pkg[i+1:], true
+	path, version = pkg[:i], pkg[i+1:]
+	if semver.IsValid(version) {
+		version = semver.Canonical(version)
+	}
+	return path, version, true
 }
 
 // JoinGoPackageVersion is used to join go path and versions
@@ -166,11 +180,11 @@ func JoinGoPackageVersion(path, version string) string {
 
 // GetBinaryFileName is used to get os based binary file name
 func GetBinaryFileName(name string) string {
-	if runtime.GOOS == "windows" {
-		if !strings.HasSuffix(name, ".exe") {
-			return name + ".exe"
-		}
+	if runtime.GOOS != "windows" {
+		return name
+	}
+	if strings.HasSuffix(strings.ToLower(name), ".exe") {
 		return name
 	}
-	return name
+	return name + ".exe"
 }